@@ -0,0 +1,235 @@
+package fls
+
+import (
+	"os"
+	"path"
+	"syscall"
+	"unsafe"
+)
+
+// Type identifies the kind of filesystem object a directory entry refers
+// to, mirroring getdents(2)'s d_type values.
+type Type uint8
+
+const (
+	TypeUnknown Type = iota
+	TypeFIFO
+	TypeChar
+	TypeDir
+	TypeBlock
+	TypeReg
+	TypeLink
+	TypeSocket
+)
+
+// TypeMask is a bitset of Type values, used to select which entry types
+// ListEntries should return.
+type TypeMask uint16
+
+// Mask returns the single-bit TypeMask corresponding to t.
+func (t Type) Mask() TypeMask {
+	return TypeMask(1) << TypeMask(t)
+}
+
+var (
+	MaskUnknown = TypeUnknown.Mask()
+	MaskFIFO    = TypeFIFO.Mask()
+	MaskChar    = TypeChar.Mask()
+	MaskDir     = TypeDir.Mask()
+	MaskBlock   = TypeBlock.Mask()
+	MaskReg     = TypeReg.Mask()
+	MaskLink    = TypeLink.Mask()
+	MaskSocket  = TypeSocket.Mask()
+)
+
+// Raw dirent file types, numbered the same way the BSDs and Linux number
+// their d_type field.
+const (
+	dtFIFO   = 1
+	dtChar   = 2
+	dtBlock  = 6
+	dtReg    = 8
+	dtLink   = 10
+	dtSocket = 12
+)
+
+func typeFromDT(dt uint64) Type {
+	switch dt {
+	case dtDir:
+		return TypeDir
+	case dtReg:
+		return TypeReg
+	case dtLink:
+		return TypeLink
+	case dtSocket:
+		return TypeSocket
+	case dtFIFO:
+		return TypeFIFO
+	case dtBlock:
+		return TypeBlock
+	case dtChar:
+		return TypeChar
+	default:
+		return TypeUnknown
+	}
+}
+
+func typeFromFileMode(mode os.FileMode) Type {
+	switch {
+	case mode&os.ModeDir != 0:
+		return TypeDir
+	case mode&os.ModeSymlink != 0:
+		return TypeLink
+	case mode&os.ModeSocket != 0:
+		return TypeSocket
+	case mode&os.ModeNamedPipe != 0:
+		return TypeFIFO
+	case mode&os.ModeCharDevice != 0:
+		return TypeChar
+	case mode&os.ModeDevice != 0:
+		return TypeBlock
+	default:
+		return TypeReg
+	}
+}
+
+// ListEntries lists the filesystem entries under dirpath whose type is set
+// in filter. If recursive is true, subdirectories are descended into
+// regardless of filter so that e.g. ListEntries(root, MaskReg, true) finds
+// every regular file in the tree. Unlike the single-directory Name a raw
+// getdents record carries, the returned Dirent.Name is the entry's path
+// relative to dirpath so that recursive results remain identifiable.
+func ListEntries(dirpath string, filter TypeMask, recursive bool) ([]Dirent, error) {
+	var entries []Dirent
+	err := eachEntry(dirpath, "", func(p string, d Dirent) error {
+		if filter&d.Type.Mask() != 0 {
+			entries = append(entries, d)
+		}
+		if recursive && d.Type == TypeDir {
+			return nil
+		}
+		return SkipDir
+	})
+	if err == SkipAll {
+		err = nil
+	}
+	return entries, err
+}
+
+func eachEntry(dirpath, parent string, fn func(path string, d Dirent) error) error {
+	return eachEntryOne(dirpath, func(d Dirent) error {
+		name := d.Name
+		p := path.Join(parent, name)
+		d.Name = p
+		switch err := fn(p, d); err {
+		case nil:
+			if d.Type == TypeDir {
+				return eachEntry(path.Join(dirpath, name), p, fn)
+			}
+			return nil
+		case SkipDir:
+			return nil
+		default:
+			return err
+		}
+	})
+}
+
+// eachEntryOne scans dirpath non-recursively, invoking fn for every entry
+// (of any type) as soon as it is parsed out of the getdents buffer.
+func eachEntryOne(dirpath string, fn func(d Dirent) error) error {
+	f, err := os.Open(dirpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	fd := int(f.Fd())
+	buf := make([]byte, 8192)
+	var nbuf, bufp int
+	var cur direntCursor
+
+	for {
+		if bufp >= nbuf {
+			bufp = 0
+			var errno error
+			nbuf, errno = readDirent(fd, buf, &cur)
+			if errno != nil {
+				return os.NewSyscallError("readdirs", errno)
+			}
+			if nbuf <= 0 {
+				break
+			}
+		}
+		consumed, d, found := nextEntry(dirpath, buf[bufp:nbuf])
+		bufp += consumed
+		if !found {
+			continue
+		}
+		if err := fn(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextEntry scans buf for the next directory entry, skipping phantom
+// zero-inode records and "." / "..". It reports how many bytes of buf were
+// consumed and, if a match was found, its metadata.
+func nextEntry(dirpath string, buf []byte) (consumed int, d Dirent, found bool) {
+	origlen := len(buf)
+	for len(buf) > 0 {
+		reclen, ok := direntReclen(buf)
+		if !ok || reclen > uint64(len(buf)) {
+			return origlen, Dirent{}, false
+		}
+		rec := buf[:reclen]
+		buf = buf[reclen:]
+		ino, ok := direntIno(rec)
+		if !ok {
+			return origlen - len(buf), Dirent{}, false
+		}
+		if ino == 0 { // File absent in directory.
+			continue
+		}
+		rawType, ok := direntType(rec)
+		if !ok {
+			return origlen - len(buf), Dirent{}, false
+		}
+
+		const namoff = uint64(unsafe.Offsetof(syscall.Dirent{}.Name))
+		namlen, ok := direntNamlen(rec)
+		if !ok || namoff+namlen > uint64(len(rec)) {
+			return origlen - len(buf), Dirent{}, false
+		}
+		n := rec[namoff : namoff+namlen]
+		if !direntNameIsExact {
+			for i, c := range n {
+				if c == 0 {
+					n = n[:i]
+					break
+				}
+			}
+		}
+		name := string(n)
+		if name == "." || name == ".." {
+			continue
+		}
+
+		typ := typeFromDT(rawType)
+		if typ == TypeUnknown {
+			// Some filesystems (old XFS on Linux) and OSes whose dirent
+			// carries no d_type at all (AIX, Solaris) never report the
+			// entry type through getdents. Fall back to an explicit stat.
+			if fi, err := os.Lstat(path.Join(dirpath, name)); err == nil {
+				typ = typeFromFileMode(fi.Mode())
+			}
+		}
+
+		return origlen - len(buf), Dirent{Name: name, Ino: ino, Type: typ}, true
+	}
+	return origlen - len(buf), Dirent{}, false
+}
@@ -0,0 +1,78 @@
+package fls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListEntriesFiltersByType(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "file"))
+	if err := os.Symlink(filepath.Join(root, "file"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ListEntries(root, MaskReg, false)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "file" || got[0].Type != TypeReg {
+		t.Fatalf("ListEntries(MaskReg) = %+v, want a single regular file named %q", got, "file")
+	}
+}
+
+func TestListEntriesRecursive(t *testing.T) {
+	root := makeTree(t, "a/b")
+	writeFile(t, filepath.Join(root, "top"))
+	writeFile(t, filepath.Join(root, "a", "mid"))
+	writeFile(t, filepath.Join(root, "a", "b", "deep"))
+
+	got, err := ListEntries(root, MaskReg, true)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	want := map[string]bool{
+		"top":                           false,
+		filepath.Join("a", "mid"):       false,
+		filepath.Join("a", "b", "deep"): false,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListEntries(recursive) = %+v, want %d entries", got, len(want))
+	}
+	for _, d := range got {
+		if _, ok := want[d.Name]; !ok {
+			t.Errorf("unexpected entry %q", d.Name)
+		}
+		if d.Type != TypeReg {
+			t.Errorf("entry %q has type %v, want TypeReg", d.Name, d.Type)
+		}
+	}
+}
+
+func TestListEntriesNonRecursiveStopsAtSubdirs(t *testing.T) {
+	root := makeTree(t, "a/b")
+	writeFile(t, filepath.Join(root, "top"))
+	writeFile(t, filepath.Join(root, "a", "mid"))
+
+	got, err := ListEntries(root, MaskReg, false)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "top" {
+		t.Fatalf("ListEntries(non-recursive) = %+v, want only %q", got, "top")
+	}
+}
+
+func TestListEntriesMissingRoot(t *testing.T) {
+	got, err := ListEntries(filepath.Join(t.TempDir(), "nope"), MaskReg|MaskDir, true)
+	if err != nil {
+		t.Fatalf("ListEntries on missing root: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+}
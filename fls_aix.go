@@ -0,0 +1,36 @@
+//go:build aix
+// +build aix
+
+package fls
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// AIX's dirent, like Linux's, derives Namlen from Reclen and NUL terminates
+// the name within that span.
+const direntNameIsExact = false
+
+func direntIno(buf []byte) (uint64, bool) {
+	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Ino), unsafe.Sizeof(syscall.Dirent{}.Ino))
+}
+
+func direntReclen(buf []byte) (uint64, bool) {
+	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Reclen), unsafe.Sizeof(syscall.Dirent{}.Reclen))
+}
+
+func direntNamlen(buf []byte) (uint64, bool) {
+	reclen, ok := direntReclen(buf)
+	if !ok {
+		return 0, false
+	}
+	return reclen - uint64(unsafe.Offsetof(syscall.Dirent{}.Name)), true
+}
+
+// AIX's dirent has no d_type field at all; every entry is reported as
+// unknown and resolved via the Lstat fallback in nextEntry (nextEntryAt for
+// the fd-relative walk).
+func direntType(buf []byte) (uint64, bool) {
+	return dtUnknown, true
+}
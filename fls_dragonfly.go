@@ -0,0 +1,35 @@
+//go:build dragonfly
+// +build dragonfly
+
+package fls
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// DragonFly's dirent carries an explicit Namlen field, so the name is not
+// NUL-terminated and must be taken at face value.
+const direntNameIsExact = true
+
+func direntIno(buf []byte) (uint64, bool) {
+	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Fileno), unsafe.Sizeof(syscall.Dirent{}.Fileno))
+}
+
+// DragonFly's dirent has no Reclen field; records are name-length plus a
+// fixed header, rounded up to an 8-byte boundary.
+func direntReclen(buf []byte) (uint64, bool) {
+	namlen, ok := direntNamlen(buf)
+	if !ok {
+		return 0, false
+	}
+	return (16 + namlen + 1 + 7) &^ 7, true
+}
+
+func direntNamlen(buf []byte) (uint64, bool) {
+	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Namlen), unsafe.Sizeof(syscall.Dirent{}.Namlen))
+}
+
+func direntType(buf []byte) (uint64, bool) {
+	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Type), unsafe.Sizeof(syscall.Dirent{}.Type))
+}
@@ -0,0 +1,27 @@
+//go:build linux && (386 || arm || mips || mipsle)
+// +build linux
+// +build 386 arm mips mipsle
+
+package fls
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// fstatat calls fstatat(2) via raw syscall.Syscall6, since these arches
+// only ever got the 32-bit-stat fstatat64(2) ABI and syscall does not
+// export a wrapper for it (unlike arm64/riscv64/loong64, whose single
+// fstatat syscall syscall.Fstatat already exposes).
+func fstatat(dirfd int, name string, flags int) (*syscall.Stat_t, error) {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	var stat syscall.Stat_t
+	_, _, errno := syscall.Syscall6(syscall.SYS_FSTATAT64, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&stat)), uintptr(flags), 0, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return &stat, nil
+}
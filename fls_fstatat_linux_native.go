@@ -0,0 +1,19 @@
+//go:build linux && (arm64 || riscv64 || loong64)
+// +build linux
+// +build arm64 riscv64 loong64
+
+package fls
+
+import "syscall"
+
+// fstatat calls fstatat(2) through syscall.Fstatat, which these arches
+// export directly (their generic Linux ABI only ever had one fstatat
+// syscall number, unlike the 32-bit-stat-vs-64-bit-stat split amd64/386/arm
+// and friends carry from before the 64-bit time_t transition).
+func fstatat(dirfd int, name string, flags int) (*syscall.Stat_t, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Fstatat(dirfd, name, &stat, flags); err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
@@ -0,0 +1,27 @@
+//go:build linux && (amd64 || ppc64 || ppc64le || s390x || mips64 || mips64le)
+// +build linux
+// +build amd64 ppc64 ppc64le s390x mips64 mips64le
+
+package fls
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// fstatat calls fstatat(2) via raw syscall.Syscall6, since these arches
+// only ever got the 64-bit-time_t newfstatat(2) ABI and syscall does not
+// export a wrapper for it (unlike arm64/riscv64/loong64, whose single
+// fstatat syscall syscall.Fstatat already exposes).
+func fstatat(dirfd int, name string, flags int) (*syscall.Stat_t, error) {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	var stat syscall.Stat_t
+	_, _, errno := syscall.Syscall6(syscall.SYS_NEWFSTATAT, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&stat)), uintptr(flags), 0, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return &stat, nil
+}
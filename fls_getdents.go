@@ -0,0 +1,14 @@
+//go:build !wasip1
+// +build !wasip1
+
+package fls
+
+import (
+	"syscall"
+)
+
+// readDirent fills buf with raw dirent records via the kernel getdents(2)
+// family. The fd itself tracks the read position, so the cursor is unused.
+func readDirent(fd int, buf []byte, _ *direntCursor) (int, error) {
+	return syscall.ReadDirent(fd, buf)
+}
@@ -0,0 +1,172 @@
+//go:build linux
+// +build linux
+
+package fls
+
+import (
+	"os"
+	"path"
+	"syscall"
+	"unsafe"
+)
+
+// atSymlinkNoFollow is AT_SYMLINK_NOFOLLOW, which has the same value on
+// every Linux architecture; syscall does not export it.
+const atSymlinkNoFollow = 0x100
+
+// ListDirectoriesAt is the fd-relative counterpart to ListDirectories: dirfd
+// is an already-open directory, and every descent opens its children with
+// openat(2) against that fd instead of re-resolving a path from the root on
+// every recursive step. That avoids both the repeated path.Join/os.Open
+// cost of a path-based walk and the TOCTOU window where a component of the
+// path could be swapped out from under it between the parent directory
+// being listed and the child being opened. The caller owns dirfd and is
+// responsible for closing it.
+func ListDirectoriesAt(dirfd int, parent string, recursive bool, output map[string]struct{}) error {
+	err := walkDirAt(dirfd, parent, func(p string, d Dirent) error {
+		output[p] = struct{}{}
+		if !recursive {
+			return SkipDir
+		}
+		return nil
+	})
+	if err == SkipAll {
+		err = nil
+	}
+	return err
+}
+
+func walkDirAt(dirfd int, parent string, fn func(path string, d Dirent) error) error {
+	return eachDirentAt(dirfd, func(name string, d Dirent) error {
+		p := path.Join(parent, name)
+		switch err := fn(p, d); err {
+		case nil:
+			childFd, oerr := syscall.Openat(dirfd, name, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_NOFOLLOW|syscall.O_CLOEXEC, 0)
+			if oerr != nil {
+				if oerr == syscall.ENOENT {
+					return nil
+				}
+				return os.NewSyscallError("openat", oerr)
+			}
+			defer syscall.Close(childFd)
+			return walkDirAt(childFd, p, fn)
+		case SkipDir:
+			return nil
+		default:
+			return err
+		}
+	})
+}
+
+// eachDirentAt scans the already-open directory dirfd non-recursively,
+// invoking fn for every subdirectory as soon as it is parsed out of the
+// getdents buffer.
+func eachDirentAt(dirfd int, fn func(name string, d Dirent) error) error {
+	buf := make([]byte, 8192)
+	var nbuf, bufp int
+	var cur direntCursor
+
+	for {
+		if bufp >= nbuf {
+			bufp = 0
+			var errno error
+			nbuf, errno = readDirent(dirfd, buf, &cur)
+			if errno != nil {
+				return os.NewSyscallError("readdirs", errno)
+			}
+			if nbuf <= 0 {
+				break
+			}
+		}
+		consumed, d, found := nextEntryAt(dirfd, buf[bufp:nbuf])
+		bufp += consumed
+		if !found {
+			continue
+		}
+		if d.Type != TypeDir {
+			continue
+		}
+		if err := fn(d.Name, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextEntryAt is nextEntry's fd-relative counterpart: a getdents record
+// reporting DT_UNKNOWN is resolved with fstatat(dirfd, name,
+// AT_SYMLINK_NOFOLLOW) instead of os.Lstat, since there is no path to stat
+// through once the walk only holds open directory fds.
+func nextEntryAt(dirfd int, buf []byte) (consumed int, d Dirent, found bool) {
+	origlen := len(buf)
+	for len(buf) > 0 {
+		reclen, ok := direntReclen(buf)
+		if !ok || reclen > uint64(len(buf)) {
+			return origlen, Dirent{}, false
+		}
+		rec := buf[:reclen]
+		buf = buf[reclen:]
+		ino, ok := direntIno(rec)
+		if !ok {
+			return origlen - len(buf), Dirent{}, false
+		}
+		if ino == 0 { // File absent in directory.
+			continue
+		}
+		rawType, ok := direntType(rec)
+		if !ok {
+			return origlen - len(buf), Dirent{}, false
+		}
+
+		const namoff = uint64(unsafe.Offsetof(syscall.Dirent{}.Name))
+		namlen, ok := direntNamlen(rec)
+		if !ok || namoff+namlen > uint64(len(rec)) {
+			return origlen - len(buf), Dirent{}, false
+		}
+		n := rec[namoff : namoff+namlen]
+		if !direntNameIsExact {
+			for i, c := range n {
+				if c == 0 {
+					n = n[:i]
+					break
+				}
+			}
+		}
+		name := string(n)
+		if name == "." || name == ".." {
+			continue
+		}
+
+		typ := typeFromDT(rawType)
+		if typ == TypeUnknown {
+			if st, err := fstatat(dirfd, name, atSymlinkNoFollow); err == nil {
+				typ = typeFromStatMode(st.Mode)
+			}
+		}
+
+		return origlen - len(buf), Dirent{Name: name, Ino: ino, Type: typ}, true
+	}
+	return origlen - len(buf), Dirent{}, false
+}
+
+// typeFromStatMode is typeFromFileMode's counterpart for a raw stat(2)
+// st_mode, used where there is no os.FileMode to hand (fstatat returns a
+// syscall.Stat_t, not an os.FileInfo).
+func typeFromStatMode(mode uint32) Type {
+	switch mode & syscall.S_IFMT {
+	case syscall.S_IFDIR:
+		return TypeDir
+	case syscall.S_IFLNK:
+		return TypeLink
+	case syscall.S_IFSOCK:
+		return TypeSocket
+	case syscall.S_IFIFO:
+		return TypeFIFO
+	case syscall.S_IFCHR:
+		return TypeChar
+	case syscall.S_IFBLK:
+		return TypeBlock
+	default:
+		return TypeReg
+	}
+}
@@ -0,0 +1,84 @@
+//go:build linux
+// +build linux
+
+package fls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openDirFd(t *testing.T, p string) int {
+	t.Helper()
+	f, err := os.Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return int(f.Fd())
+}
+
+func TestListDirectoriesAtRecursive(t *testing.T) {
+	root := makeTree(t, "a/b", "a/c", "d")
+
+	got := map[string]struct{}{}
+	if err := ListDirectoriesAt(openDirFd(t, root), "", true, got); err != nil {
+		t.Fatalf("ListDirectoriesAt: %v", err)
+	}
+	want := []string{"a", filepath.Join("a", "b"), filepath.Join("a", "c"), "d"}
+	for _, p := range want {
+		if _, ok := got[p]; !ok {
+			t.Errorf("missing %q in %v", p, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestListDirectoriesAtNonRecursive(t *testing.T) {
+	root := makeTree(t, "a/b", "d")
+
+	got := map[string]struct{}{}
+	if err := ListDirectoriesAt(openDirFd(t, root), "", false, got); err != nil {
+		t.Fatalf("ListDirectoriesAt: %v", err)
+	}
+	want := []string{"a", "d"}
+	for _, p := range want {
+		if _, ok := got[p]; !ok {
+			t.Errorf("missing %q in %v", p, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+}
+
+// A directory entry that is itself a symlink to a directory is reported by
+// getdents(2) as DT_LNK, not DT_DIR, so eachDirentAt never hands it to
+// walkDirAt in the first place; ListDirectoriesAt must not descend into it
+// or report it as a directory.
+func TestListDirectoriesAtDoesNotFollowSymlinkedDir(t *testing.T) {
+	root := makeTree(t, "real/inside")
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]struct{}{}
+	if err := ListDirectoriesAt(openDirFd(t, root), "", true, got); err != nil {
+		t.Fatalf("ListDirectoriesAt: %v", err)
+	}
+	if _, ok := got["link"]; ok {
+		t.Errorf("ListDirectoriesAt followed symlinked directory: %v", got)
+	}
+	want := []string{"real", filepath.Join("real", "inside")}
+	for _, p := range want {
+		if _, ok := got[p]; !ok {
+			t.Errorf("missing %q in %v", p, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+}
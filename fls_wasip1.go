@@ -0,0 +1,85 @@
+//go:build wasip1
+// +build wasip1
+
+package fls
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// wasip1's dirent carries an explicit Namlen field, so the name is not
+// NUL-terminated and must be taken at face value.
+const direntNameIsExact = true
+
+// sizeOfDirent is the fixed-size header preceding the variable-length name
+// in a WASI dirent record (next cookie + ino + namlen + type).
+const sizeOfDirent = 24
+
+func direntIno(buf []byte) (uint64, bool) {
+	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Ino), unsafe.Sizeof(syscall.Dirent{}.Ino))
+}
+
+func direntReclen(buf []byte) (uint64, bool) {
+	namlen, ok := direntNamlen(buf)
+	return sizeOfDirent + namlen, ok
+}
+
+func direntNamlen(buf []byte) (uint64, bool) {
+	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Namlen), unsafe.Sizeof(syscall.Dirent{}.Namlen))
+}
+
+func direntType(buf []byte) (uint64, bool) {
+	off := unsafe.Offsetof(syscall.Dirent{}.Type)
+	if uint64(off) >= uint64(len(buf)) {
+		return dtUnknown, true
+	}
+	switch syscall.Filetype(buf[off]) {
+	case syscall.FILETYPE_BLOCK_DEVICE:
+		return dtBlock, true
+	case syscall.FILETYPE_CHARACTER_DEVICE:
+		return dtChar, true
+	case syscall.FILETYPE_DIRECTORY:
+		return dtDir, true
+	case syscall.FILETYPE_REGULAR_FILE:
+		return dtReg, true
+	case syscall.FILETYPE_SOCKET_DGRAM, syscall.FILETYPE_SOCKET_STREAM:
+		return dtSocket, true
+	case syscall.FILETYPE_SYMBOLIC_LINK:
+		return dtLink, true
+	default:
+		return dtUnknown, true
+	}
+}
+
+// readDirent pages through a directory via WASI's cookie-based fd_readdir,
+// unlike the getdents(2) family the fd itself carries no read position.
+func readDirent(fd int, buf []byte, cur *direntCursor) (int, error) {
+	n, err := syscall.ReadDir(fd, buf, cur.cookie)
+	if err != nil || n <= 0 {
+		return n, err
+	}
+	if next, ok := direntNextCookie(buf[:n]); ok {
+		cur.cookie = next
+	}
+	return n, nil
+}
+
+// direntNextCookie returns the Next cookie of the last record in buf, used
+// to resume fd_readdir after this chunk.
+func direntNextCookie(buf []byte) (uint64, bool) {
+	var next uint64
+	var ok bool
+	for len(buf) > 0 {
+		reclen, rok := direntReclen(buf)
+		if !rok || reclen > uint64(len(buf)) {
+			break
+		}
+		rec := buf[:reclen]
+		buf = buf[reclen:]
+		if n, nok := readInt(rec, unsafe.Offsetof(syscall.Dirent{}.Next), unsafe.Sizeof(syscall.Dirent{}.Next)); nok {
+			next, ok = n, true
+		}
+	}
+	return next, ok
+}
@@ -1,5 +1,3 @@
-// +build linux
-
 // Copyright 2009 The Go Authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
 //
@@ -31,109 +29,38 @@
 
 package fls
 
-import (
-	"os"
-	"path"
-	"runtime"
-	"syscall"
-	"unsafe"
+// Raw dirent file types, numbered the same way the BSDs and Linux number
+// their d_type field. OSes whose dirent carries no type information at all
+// (Solaris, AIX) report dtUnknown for every entry.
+const (
+	dtUnknown = 0
+	dtDir     = 4
 )
 
-func ListDirectories(dirpath string, parent string, recursive bool, output map[string]struct{}) error {
-	f, err := os.Open(dirpath)
-	if err != nil {
-		// Ignore if this hierarchy does not exist.
-		if os.IsNotExist(err) {
-			err = nil
-		}
-		return err
-	}
-
-	fd := int(f.Fd())
-	buf := make([]byte, 8192)
-	var nbuf, bufp int
-
-	dirs := make([]string, 0, 128)
-	for {
-		if bufp >= nbuf {
-			bufp = 0
-			var errno error
-			nbuf, errno = syscall.ReadDirent(fd, buf)
-			runtime.KeepAlive(f)
-			if errno != nil {
-				return os.NewSyscallError("readdirs", errno)
-			}
-			if nbuf <= 0 {
-				break
-			}
-		}
-		var nb int
-		nb, dirs = parseDirent(buf[bufp:nbuf], dirs)
-		bufp += nb
-	}
-	f.Close()
-
-	for _, d := range dirs {
-		name := path.Join(parent, d)
-		output[name] = struct{}{}
-
-		// List subcontainers if asked to.
-		if recursive {
-			err := listDirectories(path.Join(dirpath, d), name, true, output)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+// direntCursor carries the per-directory-handle state a readDirent
+// implementation needs across refills of the getdents buffer. On systems
+// where the kernel fd itself tracks the read position (the usual POSIX
+// getdents semantics) this is unused; on systems that instead page through
+// entries via an explicit cookie (wasip1), it holds that cookie.
+type direntCursor struct {
+	cookie uint64
 }
 
-func parseDirent(buf []byte, dirs []string) (consumed int, newdirs []string) {
-	origlen := len(buf)
-	for len(buf) > 0 {
-		reclen, ok := direntReclen(buf)
-		if !ok || reclen > uint64(len(buf)) {
-			return origlen, dirs
-		}
-		rec := buf[:reclen]
-		buf = buf[reclen:]
-		ino, ok := direntIno(rec)
-		if !ok {
-			break
-		}
-		if ino == 0 { // File absent in directory.
-			continue
-		}
-		dt, ok := direntType(rec)
-		if !ok {
-			break
-		}
-		// We only grab directories.
-		if dt != syscall.DT_DIR {
-			continue
-		}
-
-		const namoff = uint64(unsafe.Offsetof(syscall.Dirent{}.Name))
-		namlen, ok := direntNamlen(rec)
-		if !ok || namoff+namlen > uint64(len(rec)) {
-			break
-		}
-		name := rec[namoff : namoff+namlen]
-		for i, c := range name {
-			if c == 0 {
-				name = name[:i]
-				break
-			}
-		}
-		// Check for useless names before allocating a string.
-		if string(name) == "." || string(name) == ".." {
-			continue
+// ListDirectories is a thin wrapper around WalkDirectories that buffers the
+// whole recursive listing into output instead of streaming it to a
+// callback.
+func ListDirectories(dirpath string, parent string, recursive bool, output map[string]struct{}) error {
+	err := walkDir(dirpath, parent, func(p string, d Dirent) error {
+		output[p] = struct{}{}
+		if !recursive {
+			return SkipDir
 		}
-
-		dirs = append(dirs, string(name))
+		return nil
+	})
+	if err == SkipAll {
+		err = nil
 	}
-	return origlen - len(buf), dirs
+	return err
 }
 
 // readInt returns the size-bytes unsigned integer in native byte order at offset off.
@@ -184,23 +111,3 @@ func readIntLE(b []byte, size uintptr) uint64 {
 		panic("common: readInt with unsupported size")
 	}
 }
-
-func direntIno(buf []byte) (uint64, bool) {
-	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Ino), unsafe.Sizeof(syscall.Dirent{}.Ino))
-}
-
-func direntReclen(buf []byte) (uint64, bool) {
-	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Reclen), unsafe.Sizeof(syscall.Dirent{}.Reclen))
-}
-
-func direntNamlen(buf []byte) (uint64, bool) {
-	reclen, ok := direntReclen(buf)
-	if !ok {
-		return 0, false
-	}
-	return reclen - uint64(unsafe.Offsetof(syscall.Dirent{}.Name)), true
-}
-
-func direntType(buf []byte) (uint64, bool) {
-	return readInt(buf, unsafe.Offsetof(syscall.Dirent{}.Type), unsafe.Sizeof(syscall.Dirent{}.Type))
-}
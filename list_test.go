@@ -0,0 +1,86 @@
+package fls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListDirectoriesNonRecursive(t *testing.T) {
+	root := makeTree(t, "a/b", "a/c", "d")
+
+	got := map[string]struct{}{}
+	if err := ListDirectories(root, "", false, got); err != nil {
+		t.Fatalf("ListDirectories: %v", err)
+	}
+	want := []string{"a", "d"}
+	for _, p := range want {
+		if _, ok := got[p]; !ok {
+			t.Errorf("missing %q in %v", p, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestListDirectoriesRecursive(t *testing.T) {
+	root := makeTree(t, "a/b", "a/c", "d")
+
+	got := map[string]struct{}{}
+	if err := ListDirectories(root, "", true, got); err != nil {
+		t.Fatalf("ListDirectories: %v", err)
+	}
+	want := []string{"a", filepath.Join("a", "b"), filepath.Join("a", "c"), "d"}
+	for _, p := range want {
+		if _, ok := got[p]; !ok {
+			t.Errorf("missing %q in %v", p, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestListDirectoriesMissingRoot(t *testing.T) {
+	got := map[string]struct{}{}
+	if err := ListDirectories(filepath.Join(t.TempDir(), "nope"), "", true, got); err != nil {
+		t.Fatalf("ListDirectories on missing root: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+}
+
+func TestListDirectoriesSeedsParent(t *testing.T) {
+	root := makeTree(t, "a/b")
+
+	got := map[string]struct{}{}
+	if err := ListDirectories(root, "prefix", true, got); err != nil {
+		t.Fatalf("ListDirectories: %v", err)
+	}
+	want := filepath.Join("prefix", "a", "b")
+	if _, ok := got[want]; !ok {
+		t.Errorf("missing %q in %v", want, got)
+	}
+}
+
+func TestListDirectoriesIgnoresFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "file"))
+	writeFile(t, filepath.Join(root, "a", "file2"))
+
+	got := map[string]struct{}{}
+	if err := ListDirectories(root, "", true, got); err != nil {
+		t.Fatalf("ListDirectories: %v", err)
+	}
+	if _, ok := got["file"]; ok {
+		t.Errorf("ListDirectories reported a plain file: %v", got)
+	}
+	if len(got) != 1 {
+		t.Errorf("got %v, want only %q", got, "a")
+	}
+}
@@ -0,0 +1,155 @@
+package fls
+
+import (
+	"path"
+)
+
+// ListFunc lists the paths under root for which keep reports true,
+// evaluating keep inline as each entry comes out of the getdents loop
+// instead of first materializing the full listing and filtering it
+// afterwards, so a directory that keep rejects costs nothing beyond the
+// entry's own parse. typ is the entry's resolved Type (see the Type*
+// constants), cast to uint8 so callers don't need to import the type
+// itself for a simple switch. If recursive is true, every subdirectory is
+// descended into regardless of keep, so that e.g.
+// ListFunc(root, isGoFile, true) finds every matching file in the tree;
+// if false, only root's immediate entries are considered.
+func ListFunc(root string, keep func(name string, typ uint8) bool, recursive bool) ([]string, error) {
+	var results []string
+	err := eachEntry(root, "", func(p string, d Dirent) error {
+		if keep(p, uint8(d.Type)) {
+			results = append(results, p)
+		}
+		if recursive && d.Type == TypeDir {
+			return nil
+		}
+		return SkipDir
+	})
+	if err == SkipAll {
+		err = nil
+	}
+	return results, err
+}
+
+// ListMatching lists the paths under root matching the glob pattern.
+// Besides the usual path.Match wildcards, a path segment of "**" matches
+// zero or more directories, evaluated segment by segment so that an entire
+// subtree is pruned as soon as a literal prefix of pattern can't match it
+// (e.g. "a/**/target" never descends into a sibling "b"). If recursive is
+// false, pattern is matched against root's immediate entries only and must
+// not contain a "/" or a "**" segment.
+func ListMatching(root string, pattern string, recursive bool) ([]string, error) {
+	if !recursive {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+		var results []string
+		err := eachEntryOne(root, func(d Dirent) error {
+			if ok, _ := path.Match(pattern, d.Name); ok {
+				results = append(results, d.Name)
+			}
+			return nil
+		})
+		return results, err
+	}
+
+	segs := splitGlobSegments(pattern)
+	for _, seg := range segs {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []string
+	err := matchSegments(root, "", segs, &results)
+	return results, err
+}
+
+// splitGlobSegments splits pattern on "/", the same separator path.Match
+// already treats specially, so each element can be matched against one
+// path component at a time.
+func splitGlobSegments(pattern string) []string {
+	var segs []string
+	for {
+		i := -1
+		for j := 0; j < len(pattern); j++ {
+			if pattern[j] == '/' {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return append(segs, pattern)
+		}
+		segs = append(segs, pattern[:i])
+		pattern = pattern[i+1:]
+	}
+}
+
+// matchSegments walks dirpath matching segs against it one path component
+// at a time, appending parent-relative matches to *results. A literal or
+// globbed segment only descends into the entries it matches, pruning every
+// other subtree; a "**" segment matches zero directories by trying the
+// remaining segments here and one-or-more by recursing into every
+// subdirectory while keeping "**" in front of the remaining segments.
+func matchSegments(dirpath, parent string, segs []string, results *[]string) error {
+	if len(segs) == 0 {
+		return nil
+	}
+	seg, rest := segs[0], segs[1:]
+
+	if seg == "**" {
+		if len(rest) == 0 {
+			// A trailing "**" matches every remaining path component, so
+			// rather than trying (and failing) to match zero further
+			// segments against nothing, collect the whole subtree.
+			return eachDescendant(dirpath, parent, results)
+		}
+		if err := matchSegments(dirpath, parent, rest, results); err != nil {
+			return err
+		}
+		return eachEntryOne(dirpath, func(d Dirent) error {
+			if d.Type != TypeDir {
+				return nil
+			}
+			return matchSegments(path.Join(dirpath, d.Name), path.Join(parent, d.Name), segs, results)
+		})
+	}
+
+	if len(rest) == 0 {
+		return eachEntryOne(dirpath, func(d Dirent) error {
+			if ok, _ := path.Match(seg, d.Name); ok {
+				*results = append(*results, path.Join(parent, d.Name))
+			}
+			return nil
+		})
+	}
+
+	return eachEntryOne(dirpath, func(d Dirent) error {
+		if d.Type != TypeDir {
+			return nil
+		}
+		if ok, _ := path.Match(seg, d.Name); !ok {
+			return nil
+		}
+		return matchSegments(path.Join(dirpath, d.Name), path.Join(parent, d.Name), rest, results)
+	})
+}
+
+// eachDescendant appends every entry under dirpath, at any depth, to
+// *results; it is what a trailing "**" segment expands to, since "**"
+// matches zero or more directories and there are no further segments left
+// to require a specific name at the end.
+func eachDescendant(dirpath, parent string, results *[]string) error {
+	return eachEntryOne(dirpath, func(d Dirent) error {
+		p := path.Join(parent, d.Name)
+		*results = append(*results, p)
+		if d.Type != TypeDir {
+			return nil
+		}
+		return eachDescendant(path.Join(dirpath, d.Name), p, results)
+	})
+}
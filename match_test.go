@@ -0,0 +1,93 @@
+package fls
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func writeFile(t *testing.T, p string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sortedStrings(ss []string) []string {
+	out := append([]string(nil), ss...)
+	sort.Strings(out)
+	return out
+}
+
+func TestListMatchingDoubleStarMiddle(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "target"))
+	writeFile(t, filepath.Join(root, "a", "b", "target"))
+	writeFile(t, filepath.Join(root, "a", "b", "c", "target"))
+	writeFile(t, filepath.Join(root, "b", "target"))
+
+	got, err := ListMatching(root, "a/**/target", true)
+	if err != nil {
+		t.Fatalf("ListMatching: %v", err)
+	}
+	want := []string{
+		path.Join("a", "target"),
+		path.Join("a", "b", "target"),
+		path.Join("a", "b", "c", "target"),
+	}
+	got, want = sortedStrings(got), sortedStrings(want)
+	if len(got) != len(want) {
+		t.Fatalf("ListMatching(a/**/target) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListMatching(a/**/target)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestListMatchingDoubleStarTrailing(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "file1"))
+	writeFile(t, filepath.Join(root, "a", "sub", "file2"))
+
+	got, err := ListMatching(root, "a/**", true)
+	if err != nil {
+		t.Fatalf("ListMatching: %v", err)
+	}
+	want := []string{
+		path.Join("a", "file1"),
+		path.Join("a", "sub"),
+		path.Join("a", "sub", "file2"),
+	}
+	got, want = sortedStrings(got), sortedStrings(want)
+	if len(got) != len(want) {
+		t.Fatalf("ListMatching(a/**) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListMatching(a/**)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestListMatchingPrunesNonMatchingSubtree(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "b", "target"))
+	writeFile(t, filepath.Join(root, "b", "unrelated"))
+
+	got, err := ListMatching(root, "a/**/target", true)
+	if err != nil {
+		t.Fatalf("ListMatching: %v", err)
+	}
+	want := []string{path.Join("a", "b", "target")}
+	got, want = sortedStrings(got), sortedStrings(want)
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("ListMatching(a/**/target) = %v, want %v", got, want)
+	}
+}
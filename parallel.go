@@ -0,0 +1,169 @@
+package fls
+
+import (
+	"path"
+	"runtime"
+	"sync"
+)
+
+// ParallelOptions configures ListDirectoriesParallel and
+// WalkDirectoriesParallel.
+type ParallelOptions struct {
+	// MaxWorkers is the number of goroutines scanning directories
+	// concurrently. Zero means runtime.GOMAXPROCS(0).
+	MaxWorkers int
+	// BufferSize overrides each worker's getdents buffer size, in bytes.
+	// Zero means 8192.
+	BufferSize int
+	// OnError, if set, is called whenever a directory fails to scan (for
+	// example EACCES while walking from "/"). Returning nil skips that
+	// directory and continues the walk; returning a non-nil error aborts
+	// the whole walk with that error.
+	OnError func(path string, err error) error
+}
+
+type parallelJob struct {
+	dirpath string
+	parent  string
+}
+
+// ListDirectoriesParallel is the concurrent counterpart to ListDirectories:
+// it buffers the whole recursive listing into a map instead of streaming it
+// to a callback. See WalkDirectoriesParallel for the traversal semantics.
+func ListDirectoriesParallel(root string, opts ParallelOptions) (map[string]struct{}, error) {
+	var found sync.Map
+	if err := WalkDirectoriesParallel(root, opts, func(p string, d Dirent) error {
+		found.Store(p, struct{}{})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	output := make(map[string]struct{})
+	found.Range(func(k, _ interface{}) bool {
+		output[k.(string)] = struct{}{}
+		return true
+	})
+	return output, nil
+}
+
+// WalkDirectoriesParallel is the concurrent counterpart to WalkDirectories.
+// A pool of opts.MaxWorkers goroutines (default runtime.GOMAXPROCS(0))
+// drains a queue of pending directories; each worker keeps its own
+// opts.BufferSize-sized (default 8192) getdents buffer so that scanning
+// sibling directories doesn't contend over a shared one. fn may be called
+// concurrently from multiple workers and must be safe for that; like
+// WalkDirectories it may return SkipDir to avoid descending into the
+// directory it was just called for, or SkipAll to stop the walk early. Any
+// other error stops every worker and is returned; if several workers fail
+// at once, the first one observed wins.
+func WalkDirectoriesParallel(root string, opts ParallelOptions, fn func(path string, d Dirent) error) error {
+	maxWorkers := opts.MaxWorkers
+	if maxWorkers <= 0 {
+		maxWorkers = runtime.GOMAXPROCS(0)
+	}
+	bufSize := opts.BufferSize
+	if bufSize <= 0 {
+		bufSize = 8192
+	}
+
+	jobs := make(chan parallelJob, maxWorkers*4)
+	done := make(chan struct{})
+	var pending sync.WaitGroup
+	var stopOnce sync.Once
+	var firstErr error
+
+	// drainJobs discards whatever is still sitting in the buffered jobs
+	// channel once the walk has been aborted. Without it, jobs pushed
+	// before the abort but not yet picked up by a worker would never get
+	// their matching pending.Done, so pending.Wait below would block
+	// forever and leak the goroutine waiting to close(jobs).
+	drainJobs := func() {
+		for range jobs {
+			pending.Done()
+		}
+	}
+
+	stop := func(err error) {
+		stopOnce.Do(func() {
+			firstErr = err
+			close(done)
+			go drainJobs()
+		})
+	}
+
+	push := func(j parallelJob) {
+		pending.Add(1)
+		select {
+		case jobs <- j:
+		case <-done:
+			pending.Done()
+		default:
+			// The buffered queue is full; hand the send off to its own
+			// goroutine instead of blocking the worker that found it.
+			go func() {
+				select {
+				case jobs <- j:
+				case <-done:
+					pending.Done()
+				}
+			}()
+		}
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < maxWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			buf := make([]byte, bufSize)
+			for {
+				select {
+				case <-done:
+					return
+				case j, ok := <-jobs:
+					if !ok {
+						return
+					}
+					walkDirParallel(j, buf, fn, opts.OnError, push, stop)
+					pending.Done()
+				}
+			}
+		}()
+	}
+
+	push(parallelJob{dirpath: root, parent: ""})
+	go func() {
+		pending.Wait()
+		close(jobs)
+	}()
+
+	workers.Wait()
+	return firstErr
+}
+
+func walkDirParallel(j parallelJob, buf []byte, fn func(string, Dirent) error, onError func(string, error) error, push func(parallelJob), stop func(error)) {
+	err := eachDirentBuf(j.dirpath, buf, func(name string, d Dirent) error {
+		p := path.Join(j.parent, name)
+		switch err := fn(p, d); err {
+		case nil:
+			push(parallelJob{dirpath: path.Join(j.dirpath, name), parent: p})
+			return nil
+		case SkipDir:
+			return nil
+		default:
+			return err
+		}
+	})
+	switch {
+	case err == nil:
+	case err == SkipAll:
+		stop(nil)
+	case onError != nil:
+		if herr := onError(j.dirpath, err); herr != nil {
+			stop(herr)
+		}
+	default:
+		stop(err)
+	}
+}
@@ -0,0 +1,86 @@
+package fls
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withTimeout runs fn in its own goroutine and fails t if fn does not return
+// within d; a hung WalkDirectoriesParallel (e.g. the pending.Wait deadlock
+// fixed alongside this test) would otherwise block the test run forever.
+func withTimeout(t *testing.T, d time.Duration, fn func() error) error {
+	t.Helper()
+	errc := make(chan error, 1)
+	go func() { errc <- fn() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(d):
+		t.Fatalf("WalkDirectoriesParallel did not return within %s", d)
+		return nil
+	}
+}
+
+func makeTree(t *testing.T, dirs ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestWalkDirectoriesParallelSkipAll(t *testing.T) {
+	root := makeTree(t, "a/b/c", "a/b/d", "x/y", "x/z")
+
+	var seen int
+	err := withTimeout(t, 5*time.Second, func() error {
+		return WalkDirectoriesParallel(root, ParallelOptions{}, func(p string, d Dirent) error {
+			seen++
+			return SkipAll
+		})
+	})
+	if err != nil {
+		t.Fatalf("WalkDirectoriesParallel: %v", err)
+	}
+	if seen == 0 {
+		t.Fatal("fn was never called")
+	}
+}
+
+func TestWalkDirectoriesParallelAbortError(t *testing.T) {
+	root := makeTree(t, "a/b/c", "a/b/d", "a/e/f", "x/y/z")
+	wantErr := errors.New("boom")
+
+	err := withTimeout(t, 5*time.Second, func() error {
+		return WalkDirectoriesParallel(root, ParallelOptions{MaxWorkers: 2}, func(p string, d Dirent) error {
+			return wantErr
+		})
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("WalkDirectoriesParallel error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestListDirectoriesParallel(t *testing.T) {
+	root := makeTree(t, "a/b", "a/c", "d")
+
+	got, err := ListDirectoriesParallel(root, ParallelOptions{})
+	if err != nil {
+		t.Fatalf("ListDirectoriesParallel: %v", err)
+	}
+	want := []string{"a", filepath.Join("a", "b"), filepath.Join("a", "c"), "d"}
+	for _, p := range want {
+		if _, ok := got[p]; !ok {
+			t.Errorf("missing %q in %v", p, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+}
@@ -0,0 +1,122 @@
+package fls
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+)
+
+// Dirent describes a single filesystem entry discovered while scanning a
+// directory.
+type Dirent struct {
+	Name string
+	Ino  uint64
+	Type Type
+}
+
+// SkipDir and SkipAll are returned by a WalkDirectories callback to control
+// the walk, mirroring the semantics of fs.WalkDir: SkipDir skips descending
+// into the directory the callback was just invoked for, while SkipAll stops
+// the walk entirely.
+var (
+	SkipDir = fs.SkipDir
+	SkipAll = fs.SkipAll
+)
+
+// EachDirent scans dirpath non-recursively, invoking fn for every
+// subdirectory as soon as it is parsed out of the getdents buffer, rather
+// than buffering the whole listing first. Any error fn returns, including
+// SkipDir or SkipAll, aborts the scan and is returned to the caller as-is.
+func EachDirent(dirpath string, fn func(name string, d Dirent) error) error {
+	return eachDirentBuf(dirpath, make([]byte, 8192), fn)
+}
+
+// eachDirentBuf is EachDirent with the getdents buffer supplied by the
+// caller, so that a long-lived worker (see ListDirectoriesParallel) can
+// reuse the same buffer across many directories instead of allocating one
+// per call.
+func eachDirentBuf(dirpath string, buf []byte, fn func(name string, d Dirent) error) error {
+	f, err := os.Open(dirpath)
+	if err != nil {
+		// Ignore if this hierarchy does not exist.
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	fd := int(f.Fd())
+	var nbuf, bufp int
+	var cur direntCursor
+
+	for {
+		if bufp >= nbuf {
+			bufp = 0
+			var errno error
+			nbuf, errno = readDirent(fd, buf, &cur)
+			runtime.KeepAlive(f)
+			if errno != nil {
+				return os.NewSyscallError("readdirs", errno)
+			}
+			if nbuf <= 0 {
+				break
+			}
+		}
+		consumed, d, found := nextDirent(dirpath, buf[bufp:nbuf])
+		bufp += consumed
+		if !found {
+			continue
+		}
+		if err := fn(d.Name, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkDirectories recursively walks the directory tree rooted at root,
+// invoking fn for every directory as soon as it is discovered instead of
+// buffering the whole recursive tree first. fn may return SkipDir to skip
+// descending into the directory it was just called for, or SkipAll to stop
+// the walk entirely; any other error aborts the walk and is returned.
+func WalkDirectories(root string, fn func(path string, d Dirent) error) error {
+	err := walkDir(root, "", fn)
+	if err == SkipAll {
+		err = nil
+	}
+	return err
+}
+
+func walkDir(dirpath, parent string, fn func(path string, d Dirent) error) error {
+	return EachDirent(dirpath, func(name string, d Dirent) error {
+		p := path.Join(parent, name)
+		switch err := fn(p, d); err {
+		case nil:
+			return walkDir(path.Join(dirpath, name), p, fn)
+		case SkipDir:
+			return nil
+		default:
+			return err
+		}
+	})
+}
+
+// nextDirent scans buf for the next subdirectory entry, skipping siblings
+// that don't qualify (non-directories, ".", ".."). It reports how many
+// bytes of buf were consumed and, if a match was found, its metadata.
+func nextDirent(dirpath string, buf []byte) (consumed int, d Dirent, found bool) {
+	origlen := len(buf)
+	for len(buf) > 0 {
+		n, e, ok := nextEntry(dirpath, buf)
+		buf = buf[n:]
+		if !ok {
+			break
+		}
+		if e.Type == TypeDir {
+			return origlen - len(buf), e, true
+		}
+	}
+	return origlen - len(buf), Dirent{}, false
+}
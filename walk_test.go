@@ -0,0 +1,120 @@
+package fls
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestEachDirentNonRecursive(t *testing.T) {
+	root := makeTree(t, "a", "b/c")
+	writeFile(t, filepath.Join(root, "file"))
+
+	var got []string
+	err := EachDirent(root, func(name string, d Dirent) error {
+		got = append(got, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachDirent: %v", err)
+	}
+	want := []string{"a", "b"}
+	got = sortedStrings(got)
+	if len(got) != len(want) {
+		t.Fatalf("EachDirent got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("EachDirent[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEachDirentMissingRoot(t *testing.T) {
+	err := EachDirent(filepath.Join(t.TempDir(), "nope"), func(name string, d Dirent) error {
+		t.Fatal("fn should not be called for a missing root")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachDirent on missing root: %v", err)
+	}
+}
+
+func TestWalkDirectoriesSkipDir(t *testing.T) {
+	root := makeTree(t, "a/b", "a/c", "d")
+
+	var got []string
+	err := WalkDirectories(root, func(p string, d Dirent) error {
+		got = append(got, p)
+		if p == "a" {
+			return SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDirectories: %v", err)
+	}
+	want := []string{"a", "d"}
+	got = sortedStrings(got)
+	if len(got) != len(want) {
+		t.Fatalf("WalkDirectories got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("WalkDirectories[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWalkDirectoriesSkipAll(t *testing.T) {
+	root := makeTree(t, "a/b", "a/c", "d")
+
+	var seen int
+	err := WalkDirectories(root, func(p string, d Dirent) error {
+		seen++
+		return SkipAll
+	})
+	if err != nil {
+		t.Fatalf("WalkDirectories: %v", err)
+	}
+	if seen != 1 {
+		t.Errorf("WalkDirectories visited %d entries after SkipAll, want 1", seen)
+	}
+}
+
+func TestWalkDirectoriesPropagatesError(t *testing.T) {
+	root := makeTree(t, "a/b")
+	wantErr := errors.New("boom")
+
+	err := WalkDirectories(root, func(p string, d Dirent) error {
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("WalkDirectories error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestWalkDirectoriesOrdersDeepest(t *testing.T) {
+	root := makeTree(t, "a/b/c")
+
+	var got []string
+	err := WalkDirectories(root, func(p string, d Dirent) error {
+		got = append(got, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDirectories: %v", err)
+	}
+	want := []string{"a", filepath.Join("a", "b"), filepath.Join("a", "b", "c")}
+	sort.Strings(got)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("WalkDirectories got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("WalkDirectories[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}